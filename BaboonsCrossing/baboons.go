@@ -2,9 +2,8 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"sync"
-	"time"
+	"sync/atomic"
 
 	"golang.org/x/sync/semaphore"
 )
@@ -44,133 +43,218 @@ func (ls *LightSwitch) Unlock(sem *sync.Mutex) {
 	ls.mutex.Unlock()
 }
 
+// CanyonOptions configures a Canyon built with NewCanyonWithPolicy, so the
+// one-lane-bridge solution below can be reused for problems other than the
+// baboon homework scenario.
+type CanyonOptions struct {
+	// MaxWeight is the total carrying capacity of the rope at any one time.
+	// Defaults to 2 (the original male=2/female=1 scenario) when <= 0.
+	MaxWeight int64
+	// WeightFunc computes the weight of a crossing baboon when Cross is
+	// called with weight <= 0. Defaults to a constant weight of 1.
+	WeightFunc func(id int, dir Direction) int64
+	// BatchSize caps how many consecutive crossings one direction may take
+	// before the turnstile forces a switch, preventing the opposite
+	// direction from starving. Mirrors the "last"/yield idea in highway.go.
+	// A value <= 0 disables the check (the original, starvation-prone
+	// behavior).
+	BatchSize int
+}
+
 type Canyon struct {
 	rope         sync.Mutex
 	capacity     *semaphore.Weighted
-	turnstile    sync.Mutex
+	turnstile    chan struct{}
+	priority     [2]chan struct{} // priority[idx(dir)]: direction-specific handoff, used to force the turnstile to a starved direction
+	waiting      [2]int32         // waiting[idx(dir)]: count of goroutines currently blocked trying to enter in that direction
+	handoffMu    sync.Mutex       // serializes releaseTurnstile's check-then-send against cancelWait's decrement-then-drain
 	ropeGrabbing sync.Mutex
 	northSwitch  *LightSwitch
 	southSwitch  *LightSwitch
+
+	weightFunc func(id int, dir Direction) int64
+	batchSize  int
+
+	streakMu sync.Mutex
+	haveLast bool
+	lastDir  Direction
+	streak   int
 }
 
-func NewCanyon() *Canyon {
+// NewCanyonWithPolicy builds a Canyon for the supplied capacity and
+// fairness policy.
+func NewCanyonWithPolicy(opts CanyonOptions) *Canyon {
+	maxWeight := opts.MaxWeight
+	if maxWeight <= 0 {
+		maxWeight = 2
+	}
+
+	turnstile := make(chan struct{}, 1)
+	turnstile <- struct{}{}
+
 	return &Canyon{
-		capacity:    semaphore.NewWeighted(2),
+		capacity:    semaphore.NewWeighted(maxWeight),
+		turnstile:   turnstile,
+		priority:    [2]chan struct{}{North: make(chan struct{}, 1), South: make(chan struct{}, 1)},
 		northSwitch: NewLightSwitch(),
 		southSwitch: NewLightSwitch(),
+		weightFunc:  opts.WeightFunc,
+		batchSize:   opts.BatchSize,
 	}
 }
 
-// --- Modified Baboons with Logging ---
+func NewCanyon() *Canyon {
+	return NewCanyonWithPolicy(CanyonOptions{MaxWeight: 2})
+}
+
+// idx maps a Direction to a priority/waiting array slot, treating anything
+// other than South as North — the same tolerant fallback Cross already uses
+// when picking a LightSwitch — instead of letting a caller-supplied
+// out-of-range Direction index out of bounds.
+func idx(dir Direction) int {
+	if dir == South {
+		return 1
+	}
+	return 0
+}
+
+// Cross attempts to move baboon id across the canyon in direction dir,
+// running action once the rope has been claimed. weight <= 0 falls back to
+// the Canyon's WeightFunc (or 1 if none was configured). Cross returns an
+// error, without running action, if ctx is canceled or times out before
+// entry is granted.
+func (c *Canyon) Cross(ctx context.Context, id int, dir Direction, weight int64, action func()) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if weight <= 0 {
+		if c.weightFunc != nil {
+			weight = c.weightFunc(id, dir)
+		} else {
+			weight = 1
+		}
+	}
 
-func (c *Canyon) Male(id int, dir Direction, action func()) {
 	mySwitch := c.northSwitch
-	if dir == South { mySwitch = c.southSwitch }
+	if dir == South {
+		mySwitch = c.southSwitch
+	}
+
+	atomic.AddInt32(&c.waiting[idx(dir)], 1)
+	select {
+	case <-c.priority[idx(dir)]:
+	case <-c.turnstile:
+	case <-ctx.Done():
+		c.cancelWait(dir)
+		return ctx.Err()
+	}
+	atomic.AddInt32(&c.waiting[idx(dir)], -1)
 
-	// Entry
-	// fmt.Printf("[%s Male %d] Arrived. Waiting for Turnstile...\n", dir, id)
-	c.turnstile.Lock()
-	// fmt.Printf("[%s Male %d] Passed Turnstile. Locking Direction...\n", dir, id)
 	mySwitch.Lock(&c.rope)
-	c.turnstile.Unlock()
+	forceYield := c.recordCrossing(dir)
+	if !forceYield {
+		c.turnstile <- struct{}{}
+	}
 
-	// Capacity
 	c.ropeGrabbing.Lock()
-	_ = c.capacity.Acquire(context.Background(), 2)
+	err := c.capacity.Acquire(ctx, weight)
 	c.ropeGrabbing.Unlock()
+	if err != nil {
+		if forceYield {
+			c.releaseTurnstile(dir)
+		}
+		mySwitch.Unlock(&c.rope)
+		return err
+	}
 
 	if action != nil { action() }
 
-	// Exit (Atomic Release)
-	c.capacity.Release(2)
+	c.capacity.Release(weight)
 	mySwitch.Unlock(&c.rope)
+	if forceYield {
+		c.releaseTurnstile(dir)
+	}
+	return nil
 }
 
-func (c *Canyon) Female(id int, dir Direction, action func()) {
-	mySwitch := c.northSwitch
-	if dir == South { mySwitch = c.southSwitch }
+// releaseTurnstile returns the turnstile token after a forced yield,
+// handing it directly to a goroutine waiting in the opposite direction if
+// one exists. A plain `turnstile <- struct{}{}` would just put the token
+// back up for grabs, and whichever direction has more goroutines queued
+// tends to win that race every time, defeating the whole point of the
+// forced yield.
+func (c *Canyon) releaseTurnstile(dir Direction) {
+	opposite := North
+	if dir == North {
+		opposite = South
+	}
 
-	// Entry
-	// fmt.Printf("[%s Female %d] Arrived. Waiting for Turnstile...\n", dir, id)
-	c.turnstile.Lock()
-	// fmt.Printf("[%s Female %d] Passed Turnstile. Locking Direction...\n", dir, id)
-	mySwitch.Lock(&c.rope) 
-	c.turnstile.Unlock()
+	c.handoffMu.Lock()
+	defer c.handoffMu.Unlock()
 
-	// Capacity
-	c.ropeGrabbing.Lock()
-	_ = c.capacity.Acquire(context.Background(), 1)
-	c.ropeGrabbing.Unlock()
+	if atomic.LoadInt32(&c.waiting[idx(opposite)]) > 0 {
+		select {
+		case c.priority[idx(opposite)] <- struct{}{}:
+			return
+		default:
+		}
+	}
+	c.turnstile <- struct{}{}
+}
 
-	if action != nil { action() }
+// cancelWait backs a goroutine out of c.waiting after its context was
+// canceled while it sat in Cross's select. It must run under handoffMu so
+// it can't race releaseTurnstile: without the lock, a handoff meant for
+// this goroutine could be deposited in c.priority just as it bails out via
+// ctx.Done() instead of receiving it, stranding the canyon's one turnstile
+// token in a direction-specific channel that may never be read again.
+func (c *Canyon) cancelWait(dir Direction) {
+	c.handoffMu.Lock()
+	defer c.handoffMu.Unlock()
 
-	// Exit
-	c.capacity.Release(1)
-	mySwitch.Unlock(&c.rope)
+	atomic.AddInt32(&c.waiting[idx(dir)], -1)
+	select {
+	case <-c.priority[idx(dir)]:
+		c.turnstile <- struct{}{}
+	default:
+	}
 }
 
-// --- The Scenario ---
-
-func main() {
-	c := NewCanyon()
-	var wg sync.WaitGroup
-
-	start := time.Now()
-	log := func(msg string) {
-		fmt.Printf("[%04dms] %s\n", time.Since(start).Milliseconds(), msg)
-	}
-
-	// 1. Start Initial North Stream (They get the rope)
-	log("--- PHASE 1: North Stream Starts ---")
-	wg.Add(2)
-	go func() {
-		defer wg.Done()
-		c.Male(1, North, func() {
-			log("North Male 1 (Group 1) CROSSING...")
-			time.Sleep(200 * time.Millisecond) // Long crossing
-			log("North Male 1 (Group 1) FINISHED")
-		})
-	}()
-	go func() {
-		defer wg.Done()
-		time.Sleep(10 * time.Millisecond) // Enters slightly later, but joins stream
-		c.Female(2, North, func() {
-			log("North Female 2 (Group 1) CROSSING...")
-			time.Sleep(200 * time.Millisecond)
-			log("North Female 2 (Group 1) FINISHED")
-		})
-	}()
-
-	time.Sleep(50 * time.Millisecond)
-
-	// 2. South Arrives (Should Block at Rope, Hold Turnstile)
-	log("--- PHASE 2: South Arrives (Should block) ---")
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		log("South Male 3 ARRIVES. Trying to enter...")
-		c.Male(3, South, func() {
-			log(">>> South Male 3 CROSSING (Finally) <<<")
-			time.Sleep(100 * time.Millisecond)
-			log("South Male 3 FINISHED")
-		})
-	}()
-
-	time.Sleep(50 * time.Millisecond)
-
-	// 3. Late North Arrives (Should Block at Turnstile)
-	log("--- PHASE 3: Late North Arrives (Should wait for South) ---")
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		log("North Female 4 (Late) ARRIVES. Trying to enter...")
-		c.Female(4, North, func() {
-			log("North Female 4 (Late) CROSSING...")
-			time.Sleep(100 * time.Millisecond)
-			log("North Female 4 (Late) FINISHED")
-		})
-	}()
-
-	wg.Wait()
-	log("--- Simulation Complete ---")
-}
\ No newline at end of file
+// recordCrossing tracks consecutive same-direction crossings and reports
+// whether this crossing has hit the batch limit, in which case the caller
+// must hold the turnstile a little longer to give the other direction a
+// fair shot at it.
+func (c *Canyon) recordCrossing(dir Direction) bool {
+	if c.batchSize <= 0 {
+		return false
+	}
+
+	c.streakMu.Lock()
+	defer c.streakMu.Unlock()
+
+	if c.haveLast && c.lastDir == dir {
+		c.streak++
+	} else {
+		c.haveLast = true
+		c.lastDir = dir
+		c.streak = 1
+	}
+
+	if c.streak >= c.batchSize {
+		c.streak = 0
+		return true
+	}
+	return false
+}
+
+// Male is a convenience wrapper around Cross for the original weight-2
+// scenario.
+func (c *Canyon) Male(id int, dir Direction, action func()) {
+	_ = c.Cross(context.Background(), id, dir, 2, action)
+}
+
+// Female is a convenience wrapper around Cross for the original weight-1
+// scenario.
+func (c *Canyon) Female(id int, dir Direction, action func()) {
+	_ = c.Cross(context.Background(), id, dir, 1, action)
+}
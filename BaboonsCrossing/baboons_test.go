@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestCanyonBatchSizeBoundsStreaks verifies the turnstile/batch-size policy
+// from CanyonOptions: even when one direction floods far more crossings than
+// the other, no direction may run more than BatchSize crossings in a row,
+// and every crossing still completes (no deadlock from the forced yields).
+// MaxWeight is pinned to 1 so the rope's own FIFO semaphore serializes every
+// crossing, so the recorded order is the true crossing order rather than
+// an interleaving of overlapping actions. Each
+// crossing sleeps briefly, like the real scenario in main.go, so the other
+// goroutines actually get scheduled in between instead of one direction
+// running start-to-finish before the other is ever woken.
+func TestCanyonBatchSizeBoundsStreaks(t *testing.T) {
+	const batchSize = 3
+	const northCount = 30
+	const southCount = 6
+	const crossingTime = time.Millisecond
+
+	c := NewCanyonWithPolicy(CanyonOptions{MaxWeight: 1, BatchSize: batchSize})
+
+	var mu sync.Mutex
+	var order []Direction
+	record := func(dir Direction) {
+		mu.Lock()
+		order = append(order, dir)
+		mu.Unlock()
+		time.Sleep(crossingTime)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(northCount + southCount)
+	for i := 0; i < northCount; i++ {
+		i := i
+		go func() {
+			defer wg.Done()
+			if err := c.Cross(ctx, i, North, 1, func() { record(North) }); err != nil {
+				t.Errorf("North %d: Cross: %v", i, err)
+			}
+		}()
+	}
+	for i := 0; i < southCount; i++ {
+		i := i
+		go func() {
+			defer wg.Done()
+			if err := c.Cross(ctx, 1000+i, South, 1, func() { record(South) }); err != nil {
+				t.Errorf("South %d: Cross: %v", i, err)
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Cross calls never completed; suspected deadlock between the turnstile and the rope")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(order) != northCount+southCount {
+		t.Fatalf("recorded %d crossings, want %d", len(order), northCount+southCount)
+	}
+
+	// Once South's last baboon has crossed, North has nothing left to yield
+	// to, so only bound streaks up through that point; North running long
+	// afterward is correct, not starvation.
+	lastSouth := -1
+	for i, d := range order {
+		if d == South {
+			lastSouth = i
+		}
+	}
+
+	streak := 1
+	for i := 1; i <= lastSouth; i++ {
+		if order[i] == order[i-1] {
+			streak++
+		} else {
+			streak = 1
+		}
+		if streak > batchSize {
+			t.Fatalf("direction %v ran %d crossings in a row at position %d while South still had baboons waiting, want at most BatchSize=%d: %v",
+				order[i], streak, i, batchSize, order)
+		}
+	}
+}
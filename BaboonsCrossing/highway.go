@@ -1,9 +1,18 @@
+//go:build highway
+
+// This file is the original counting-semaphore solution to the highway
+// one-lane-bridge problem that baboons.go's turnstile/batch-size idea was
+// modeled on. It lives behind the "highway" build tag since it ships its
+// own func main(), separate from the canyon scenario built by default:
+//
+//	go run -tags highway .
 package main
 
 import (
 	"fmt"
-	"highway/semaphore"
 	"time"
+
+	"baboonscrossing/semaphore"
 )
 
 const (
@@ -0,0 +1,74 @@
+//go:build !highway
+
+// --- The Scenario ---
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+func main() {
+	c := NewCanyon()
+	var wg sync.WaitGroup
+
+	start := time.Now()
+	log := func(msg string) {
+		fmt.Printf("[%04dms] %s\n", time.Since(start).Milliseconds(), msg)
+	}
+
+	// 1. Start Initial North Stream (They get the rope)
+	log("--- PHASE 1: North Stream Starts ---")
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		c.Male(1, North, func() {
+			log("North Male 1 (Group 1) CROSSING...")
+			time.Sleep(200 * time.Millisecond) // Long crossing
+			log("North Male 1 (Group 1) FINISHED")
+		})
+	}()
+	go func() {
+		defer wg.Done()
+		time.Sleep(10 * time.Millisecond) // Enters slightly later, but joins stream
+		c.Female(2, North, func() {
+			log("North Female 2 (Group 1) CROSSING...")
+			time.Sleep(200 * time.Millisecond)
+			log("North Female 2 (Group 1) FINISHED")
+		})
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+
+	// 2. South Arrives (Should Block at Rope, Hold Turnstile)
+	log("--- PHASE 2: South Arrives (Should block) ---")
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		log("South Male 3 ARRIVES. Trying to enter...")
+		c.Male(3, South, func() {
+			log(">>> South Male 3 CROSSING (Finally) <<<")
+			time.Sleep(100 * time.Millisecond)
+			log("South Male 3 FINISHED")
+		})
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+
+	// 3. Late North Arrives (Should Block at Turnstile)
+	log("--- PHASE 3: Late North Arrives (Should wait for South) ---")
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		log("North Female 4 (Late) ARRIVES. Trying to enter...")
+		c.Female(4, North, func() {
+			log("North Female 4 (Late) CROSSING...")
+			time.Sleep(100 * time.Millisecond)
+			log("North Female 4 (Late) FINISHED")
+		})
+	}()
+
+	wg.Wait()
+	log("--- Simulation Complete ---")
+}
@@ -0,0 +1,93 @@
+//go:build !tcp
+
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+func main() {
+	rand.Seed(time.Now().UnixNano())
+
+	if len(os.Args) < 3 {
+		fmt.Println("Usage: go run . <N nodes> <extra edges per node>")
+		os.Exit(2)
+	}
+
+	numNodes, _ := strconv.Atoi(os.Args[1])
+	extraEdgesFactor, _ := strconv.Atoi(os.Args[2])
+	extraEdges := numNodes * extraEdgesFactor
+
+	recorder := NewEventRecorder("simulation_events.json")
+	adj, weights := GenerateWeightedGraph(numNodes, extraEdges)
+
+	var edges [][2]int
+	var edgeWeights []float64
+	nodes := make([]int, numNodes)
+	for u, neighbors := range adj {
+		nodes[u] = u
+		for _, v := range neighbors {
+			if u < v {
+				edges = append(edges, [2]int{u, v})
+				edgeWeights = append(edgeWeights, weights[[2]int{u, v}])
+			}
+		}
+	}
+
+	// Record initialization topology.
+	recorder.Record(Event{
+		Type:        "INIT",
+		Nodes:       nodes,
+		Edges:       edges,
+		EdgeWeights: edgeWeights,
+	})
+
+	network := make(map[int]chan Message)
+	for i := 0; i < numNodes; i++ {
+		network[i] = make(chan Message, 1000)
+	}
+
+	// 20 msgs/sec with a burst of 5 is enough headroom for normal
+	// PROPOSE/ACCEPT traffic while still throttling a node that's being
+	// flooded; idle buckets are swept after a minute.
+	const (
+		limiterRate  = 20.0
+		limiterBurst = 5.0
+		limiterTTL   = time.Minute
+	)
+
+	var nodeInstances []*Node
+	var limiters []*RateLimiter
+	for i := 0; i < numNodes; i++ {
+		neighborWeights := make(map[int]float64, len(adj[i]))
+		for _, v := range adj[i] {
+			neighborWeights[v] = weights[[2]int{i, v}]
+		}
+
+		transport := NewChannelTransport(network[i], network)
+		limiter := NewRateLimiter(limiterRate, limiterBurst, limiterTTL)
+		limiters = append(limiters, limiter)
+		nodeInstances = append(nodeInstances, InitNode(i, neighborWeights, transport, limiter, recorder))
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(numNodes)
+
+	for i, node := range nodeInstances {
+		go func(n *Node, limiter *RateLimiter) {
+			defer wg.Done()
+			defer limiter.Close()
+			n.makePairs()
+		}(node, limiters[i])
+	}
+
+	wg.Wait()
+	recorder.Close()
+
+	fmt.Println("Simulation complete. Event log written to 'simulation_events.json'.")
+}
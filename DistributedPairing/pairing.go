@@ -6,8 +6,6 @@ import (
 	"math/rand"
 	"os"
 	"slices"
-	"strconv"
-	"sync"
 	"time"
 )
 
@@ -17,6 +15,7 @@ type MsgType int
 const (
 	PROPOSE MsgType = iota
 	ACCEPT
+	REJECT
 	MATCHED_MSG
 )
 
@@ -27,6 +26,8 @@ func (m MsgType) String() string {
 		return "PROPOSE"
 	case ACCEPT:
 		return "ACCEPT"
+	case REJECT:
+		return "REJECT"
 	case MATCHED_MSG:
 		return "MATCHED"
 	default:
@@ -39,6 +40,28 @@ func (m MsgType) MarshalJSON() ([]byte, error) {
 	return json.Marshal(m.String())
 }
 
+// UnmarshalJSON is the inverse of MarshalJSON, so a Message round-trips
+// through JSON (and therefore through TCPTransport) intact.
+func (m *MsgType) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	switch s {
+	case "PROPOSE":
+		*m = PROPOSE
+	case "ACCEPT":
+		*m = ACCEPT
+	case "REJECT":
+		*m = REJECT
+	case "MATCHED":
+		*m = MATCHED_MSG
+	default:
+		return fmt.Errorf("msgtype: unknown value %q", s)
+	}
+	return nil
+}
+
 // NodeState tracks the algorithm phase for visualization.
 type NodeState string
 
@@ -49,23 +72,28 @@ const (
 	MATCHED  NodeState = "MATCHED"
 )
 
-// Message encapsulates the data sent between nodes.
+// Message encapsulates the data sent between nodes. Weight carries the
+// sender's edge weight to the target, used for tie-breaking in the
+// weighted matching mode; it is 1 in the unweighted mode, where every edge
+// weighs 1.
 type Message struct {
 	Type   MsgType `json:"type"`
 	Sender int     `json:"sender"`
 	Target int     `json:"target"`
+	Weight float64 `json:"weight,omitempty"`
 }
 
 // Event represents a single atomic occurrence in the simulation.
 type Event struct {
-	Timestamp int64     `json:"timestamp"`
-	Type      string    `json:"type"` 
-	Node      int       `json:"node"` 
-	State     NodeState `json:"state,omitempty"`
-	Msg       *Message  `json:"msg,omitempty"`
-	Partner   int       `json:"partner"` 
-	Nodes     []int     `json:"nodes,omitempty"`
-	Edges     [][2]int  `json:"edges,omitempty"`
+	Timestamp   int64     `json:"timestamp"`
+	Type        string    `json:"type"`
+	Node        int       `json:"node"`
+	State       NodeState `json:"state,omitempty"`
+	Msg         *Message  `json:"msg,omitempty"`
+	Partner     int       `json:"partner"`
+	Nodes       []int     `json:"nodes,omitempty"`
+	Edges       [][2]int  `json:"edges,omitempty"`
+	EdgeWeights []float64 `json:"edgeWeights,omitempty"` // aligned with Edges, when the graph is weighted
 }
 
 // EventRecorder acts as a synchronized sink for all simulation events.
@@ -122,29 +150,38 @@ func (r *EventRecorder) Close() {
 }
 
 // Node represents an independent concurrent process in the graph.
+// neighbors maps each still-available neighbor to the weight of the edge
+// to it (1 for every edge in the unweighted mode).
 type Node struct {
-	ID        int                  
-	State     NodeState            
-	Inbox     chan Message         
-	Network   map[int]chan Message 
-	neighbors map[int]bool         
-	pair      int                  
-	recorder  *EventRecorder       
+	ID        int
+	State     NodeState
+	transport Transport
+	limiter   *RateLimiter
+	neighbors map[int]float64
+	awaiting  int // node this Node is currently waiting on a reply from, -1 if none
+	pair      int
+	recorder  *EventRecorder
 }
 
-// InitNode provisions a new Node with its initial topology.
-func InitNode(id int, neighbors []int, inbox chan Message, network map[int]chan Message, rec *EventRecorder) *Node {
-	neighborSet := make(map[int]bool)
-	for _, n := range neighbors {
-		neighborSet[n] = true
+// InitNode provisions a new Node with its initial topology. transport may
+// be a ChannelTransport (in-process simulation) or a TCPTransport (separate
+// OS processes); makePairs, propose, and listen don't care which. limiter
+// throttles outgoing traffic per destination so a burst of PROPOSE/ACCEPT
+// messages can't flood a neighbor. neighborWeights maps each neighbor ID to
+// its edge weight (pass 1 for every neighbor to run the unweighted mode).
+func InitNode(id int, neighborWeights map[int]float64, transport Transport, limiter *RateLimiter, rec *EventRecorder) *Node {
+	neighbors := make(map[int]float64, len(neighborWeights))
+	for nid, w := range neighborWeights {
+		neighbors[nid] = w
 	}
 
 	return &Node{
 		ID:        id,
 		State:     SINGLE,
-		Inbox:     inbox,
-		Network:   network,
-		neighbors: neighborSet,
+		transport: transport,
+		limiter:   limiter,
+		neighbors: neighbors,
+		awaiting:  -1,
 		pair:      id,
 		recorder:  rec,
 	}
@@ -169,20 +206,32 @@ func (n *Node) changeState(newState NodeState) {
 	}
 }
 
-// send issues a non-blocking message to a target node with simulated latency.
-func (n *Node) send(to int, typ MsgType) {
-	simulateNetworkLatency()
-	msg := Message{Type: typ, Sender: n.ID, Target: to}
-	select {
-	case n.Network[to] <- msg:
+// send issues a message to a target node via whichever Transport this node
+// was built with. It is throttled by this
+// node's RateLimiter, recording a MSG_DROPPED event instead of sending
+// when the destination's bucket is empty. weight is this node's edge
+// weight to to, carried along for tie-breaking in the weighted mode.
+func (n *Node) send(to int, typ MsgType, weight float64) {
+	msg := Message{Type: typ, Sender: n.ID, Target: to, Weight: weight}
+
+	if !n.limiter.Allow(to) {
 		n.recorder.Record(Event{
-			Type: "MSG_SENT",
+			Type: "MSG_DROPPED",
 			Node: n.ID,
 			Msg:  &msg,
 		})
-	default:
-		// Drop message if channel is full to prevent absolute deadlock.
+		return
+	}
+
+	if err := n.transport.Send(to, msg); err != nil {
+		// Drop message to prevent absolute deadlock, as before.
+		return
 	}
+	n.recorder.Record(Event{
+		Type: "MSG_SENT",
+		Node: n.ID,
+		Msg:  &msg,
+	})
 }
 
 // finalize registers a successful pairing and notifies remaining neighbors.
@@ -196,88 +245,124 @@ func (n *Node) finalize(partnerID int) {
 		Partner: partnerID,
 	})
 
-	for nid := range n.neighbors {
+	for nid, w := range n.neighbors {
 		if nid != partnerID {
-			n.send(nid, MATCHED_MSG)
+			n.send(nid, MATCHED_MSG, w)
 		}
 	}
 }
 
-// propose attempts to pair with the specified high-priority target.
-func (n *Node) propose(targetID int) {
-	n.changeState(PROPOSER)
-	n.send(targetID, PROPOSE)
-
-	waiting := true
-	for waiting {
-		msg := <-n.Inbox
-		simulateNetworkLatency() // Mimic processing time
-		n.recorder.Record(Event{Type: "MSG_RECV", Node: n.ID, Msg: &msg})
-
-		switch msg.Type {
-		case ACCEPT:
-			if msg.Sender == targetID {
-				n.finalize(targetID)
-				return
-			}
-		case MATCHED_MSG:
-			delete(n.neighbors, msg.Sender)
-			if msg.Sender == targetID {
-				waiting = false
-			}
-		case PROPOSE:
-			// Ignore proposals while actively waiting for a response to our own.
+// heaviestNeighbor returns the currently-available neighbor with the
+// largest edge weight (ties broken by higher ID, as the old max-ID rule
+// did), or -1 if none remain.
+func (n *Node) heaviestNeighbor() int {
+	best := -1
+	bestWeight := -1.0
+	for id, w := range n.neighbors {
+		if w > bestWeight || (w == bestWeight && id > best) {
+			best, bestWeight = id, w
 		}
 	}
+	return best
 }
 
-// listen waits for incoming proposals and greedily accepts the first valid one.
-func (n *Node) listen() {
-	n.changeState(LISTENER)
+// propose sends a PROPOSE to this node's heaviest still-available
+// neighbor, or marks the node SINGLE if none remain. This is Preis's
+// 1/2-approximation weighted maximal matching: proposing to the heaviest
+// neighbor instead of the highest ID.
+func (n *Node) propose() {
+	target := n.heaviestNeighbor()
+	if target == -1 {
+		n.awaiting = -1
+		n.changeState(SINGLE)
+		return
+	}
+
+	n.awaiting = target
+	n.changeState(PROPOSER)
+	n.send(target, PROPOSE, n.neighbors[target])
+}
 
-	msg := <-n.Inbox
+// listen handles the next incoming message: an unsolicited PROPOSE from a
+// neighbor, the ACCEPT/REJECT reply to our own outstanding proposal, or a
+// MATCHED notification from a neighbor that paired up elsewhere. A
+// proposal is only accepted if the sender is, in turn, this node's own
+// heaviest available neighbor. A REJECT prunes the proposer from both
+// sides' neighbor sets, not just the rejected proposer's: otherwise a node
+// that has rejected everyone and gone SINGLE can still be sitting in a
+// neighbor's map, which proposes to it long after it has stopped
+// listening and hangs forever awaiting a reply that will never come.
+func (n *Node) listen() {
+	msg, err := n.transport.Recv()
+	if err != nil {
+		return
+	}
 	simulateNetworkLatency() // Mimic processing time
 	n.recorder.Record(Event{Type: "MSG_RECV", Node: n.ID, Msg: &msg})
 
 	switch msg.Type {
 	case PROPOSE:
-		n.send(msg.Sender, ACCEPT)
-		n.finalize(msg.Sender)
+		if n.heaviestNeighbor() == msg.Sender {
+			n.send(msg.Sender, ACCEPT, n.neighbors[msg.Sender])
+			n.finalize(msg.Sender)
+		} else {
+			n.send(msg.Sender, REJECT, n.neighbors[msg.Sender])
+			delete(n.neighbors, msg.Sender)
+		}
+	case ACCEPT:
+		if msg.Sender == n.awaiting {
+			n.finalize(msg.Sender)
+		}
+	case REJECT:
+		if msg.Sender == n.awaiting {
+			delete(n.neighbors, msg.Sender)
+			n.propose()
+		}
 	case MATCHED_MSG:
+		wasAwaiting := msg.Sender == n.awaiting
 		delete(n.neighbors, msg.Sender)
+		if wasAwaiting {
+			n.propose()
+		}
 	}
 }
 
-// makePairs executes the core maximal matching algorithm.
+// makePairs executes the weighted maximal matching algorithm: propose to
+// the heaviest available neighbor, then keep handling messages until
+// either this node matches or runs out of neighbors to try.
 func (n *Node) makePairs() {
-	for n.pair == n.ID {
-		if len(n.neighbors) == 0 {
-			n.changeState(SINGLE)
-			return
-		}
-
-		maxNeighborID := -1
-		for id := range n.neighbors {
-			if id > maxNeighborID {
-				maxNeighborID = id
-			}
-		}
-
-		if n.ID > maxNeighborID {
-			n.propose(maxNeighborID)
-		} else {
-			n.listen()
-		}
+	n.propose()
+	for n.pair == n.ID && n.awaiting != -1 {
+		n.listen()
 	}
 }
 
-// GenerateGraph creates a connected graph with additional random edges.
+// GenerateGraph creates a connected graph with additional random edges. It
+// returns only the adjacency map, with no edge weights; use
+// GenerateWeightedGraph for the weighted matching mode.
 func GenerateGraph(numNodes int, extraEdges int) map[int][]int {
+	adj, _ := generateGraph(numNodes, extraEdges, false)
+	return adj
+}
+
+// GenerateWeightedGraph is GenerateGraph plus a random integer weight in
+// [1, 10] for every edge, keyed by its endpoints in both orders so either
+// side of an edge can look its weight up directly.
+func GenerateWeightedGraph(numNodes int, extraEdges int) (map[int][]int, map[[2]int]float64) {
+	return generateGraph(numNodes, extraEdges, true)
+}
+
+func generateGraph(numNodes int, extraEdges int, weighted bool) (map[int][]int, map[[2]int]float64) {
 	adj := make(map[int][]int)
 	for i := 0; i < numNodes; i++ {
 		adj[i] = []int{}
 	}
 
+	var weights map[[2]int]float64
+	if weighted {
+		weights = make(map[[2]int]float64)
+	}
+
 	shuffledIDs := make([]int, numNodes)
 	for i := range numNodes {
 		shuffledIDs[i] = i
@@ -292,6 +377,11 @@ func GenerateGraph(numNodes int, extraEdges int) map[int][]int {
 		}
 		adj[u] = append(adj[u], v)
 		adj[v] = append(adj[v], u)
+		if weighted {
+			w := float64(1 + rand.Intn(10))
+			weights[[2]int{u, v}] = w
+			weights[[2]int{v, u}] = w
+		}
 	}
 
 	for i := 0; i < numNodes-1; i++ {
@@ -300,64 +390,5 @@ func GenerateGraph(numNodes int, extraEdges int) map[int][]int {
 	for i := 0; i < extraEdges; i++ {
 		addEdge(rand.Intn(numNodes), rand.Intn(numNodes))
 	}
-	return adj
+	return adj, weights
 }
-
-func main() {
-	rand.Seed(time.Now().UnixNano())
-
-	if len(os.Args) < 3 {
-		fmt.Println("Usage: go run main.go <N nodes> <E extra edges>")
-		os.Exit(2)
-	}
-
-	numNodes, _ := strconv.Atoi(os.Args[1])
-	extraEdgesFactor, _ := strconv.Atoi(os.Args[2])
-	extraEdges := numNodes * extraEdgesFactor
-
-	recorder := NewEventRecorder("simulation_events.json")
-	adj := GenerateGraph(numNodes, extraEdges)
-
-	var edges [][2]int
-	nodes := make([]int, numNodes)
-	for u, neighbors := range adj {
-		nodes[u] = u
-		for _, v := range neighbors {
-			if u < v {
-				edges = append(edges, [2]int{u, v})
-			}
-		}
-	}
-	
-	// Record initialization topology. 
-	recorder.Record(Event{
-		Type:  "INIT",
-		Nodes: nodes,
-		Edges: edges,
-	})
-
-	network := make(map[int]chan Message)
-	for i := 0; i < numNodes; i++ {
-		network[i] = make(chan Message, 1000)
-	}
-
-	var nodeInstances []*Node
-	for i := 0; i < numNodes; i++ {
-		nodeInstances = append(nodeInstances, InitNode(i, adj[i], network[i], network, recorder))
-	}
-
-	var wg sync.WaitGroup
-	wg.Add(numNodes)
-
-	for _, node := range nodeInstances {
-		go func(n *Node) {
-			defer wg.Done()
-			n.makePairs()
-		}(node)
-	}
-
-	wg.Wait()
-	recorder.Close()
-
-	fmt.Println("Simulation complete. Event log written to 'simulation_events.json'.")
-}
\ No newline at end of file
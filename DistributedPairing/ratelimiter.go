@@ -0,0 +1,100 @@
+package main
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a per-destination token-bucket limiter modeled after the
+// one WireGuard uses to throttle handshake packets: each destination gets
+// its own bucket of up to burst tokens, refilled lazily at rate tokens per
+// second on every Allow call. It replaces the flat 50ms sleep in
+// simulateNetworkLatency with a congestion model that can actually drop
+// messages once a destination is being hammered.
+type RateLimiter struct {
+	rate  float64
+	burst float64
+	ttl   time.Duration
+
+	mu      sync.Mutex
+	buckets map[int]*bucket
+
+	stop chan struct{}
+}
+
+type bucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// NewRateLimiter builds a RateLimiter allowing rate messages/sec per
+// destination, bursting up to burst, and evicting buckets idle longer than
+// ttl so memory doesn't grow with every destination ever seen.
+func NewRateLimiter(rate, burst float64, ttl time.Duration) *RateLimiter {
+	rl := &RateLimiter{
+		rate:    rate,
+		burst:   burst,
+		ttl:     ttl,
+		buckets: make(map[int]*bucket),
+		stop:    make(chan struct{}),
+	}
+	go rl.sweep()
+	return rl
+}
+
+// Allow reports whether a message to `to` may be sent now, consuming a
+// token from its bucket if so.
+func (rl *RateLimiter) Allow(to int) bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+	b, ok := rl.buckets[to]
+	if !ok {
+		b = &bucket{tokens: rl.burst, lastSeen: now}
+		rl.buckets[to] = b
+	}
+
+	elapsed := now.Sub(b.lastSeen).Seconds()
+	b.tokens = math.Min(rl.burst, b.tokens+elapsed*rl.rate)
+	b.lastSeen = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// sweep periodically evicts buckets that have gone idle for longer than
+// ttl, bounding memory when a node sends to many destinations over time.
+// It exits once Close closes rl.stop.
+func (rl *RateLimiter) sweep() {
+	ticker := time.NewTicker(rl.ttl)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			rl.mu.Lock()
+			cutoff := now.Add(-rl.ttl)
+			for id, b := range rl.buckets {
+				if b.lastSeen.Before(cutoff) {
+					delete(rl.buckets, id)
+				}
+			}
+			rl.mu.Unlock()
+		case <-rl.stop:
+			return
+		}
+	}
+}
+
+// Close stops the background sweeper goroutine. Callers that create many
+// RateLimiters over a process's lifetime (e.g. one per node in a test)
+// should Close each one once it's no longer needed, or every instance
+// leaks its sweeper goroutine for good.
+func (rl *RateLimiter) Close() {
+	close(rl.stop)
+}
@@ -0,0 +1,277 @@
+// Command replay consumes a simulation_events.json log produced by
+// EventRecorder and reconstructs the run it describes, checking that the
+// matching algorithm's invariants held throughout. It exits non-zero if
+// any invariant is violated, so the recorder is useful for CI and not just
+// for the visualizer.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Message mirrors the JSON shape written for Event.Msg by the simulator.
+type Message struct {
+	Type   string  `json:"type"`
+	Sender int     `json:"sender"`
+	Target int     `json:"target"`
+	Weight float64 `json:"weight,omitempty"`
+}
+
+// Event mirrors the JSON shape written by EventRecorder.
+type Event struct {
+	Timestamp   int64     `json:"timestamp"`
+	Type        string    `json:"type"`
+	Node        int       `json:"node"`
+	State       string    `json:"state,omitempty"`
+	Msg         *Message  `json:"msg,omitempty"`
+	Partner     int       `json:"partner"`
+	Nodes       []int     `json:"nodes,omitempty"`
+	Edges       [][2]int  `json:"edges,omitempty"`
+	EdgeWeights []float64 `json:"edgeWeights,omitempty"`
+}
+
+// Report summarizes a replayed run. MatchingWeight/OptimalWeight are only
+// populated when the replayed log came from a weighted run (its INIT event
+// carried EdgeWeights); a weighted maximal matching is only a 1/2
+// approximation of the optimum, so falling short of OptimalWeight is
+// informational, not an invariant violation.
+type Report struct {
+	MatchingSize   int
+	BruteForceSize int
+	Weighted       bool
+	MatchingWeight float64
+	OptimalWeight  float64
+	Violations     []string
+}
+
+// bruteForceCutoff bounds the brute-force maximum-matching search; above
+// this many nodes the comparison is skipped rather than left to run
+// combinatorially long.
+const bruteForceCutoff = 14
+
+// loadEvents reads and decodes an event log previously written by
+// EventRecorder.
+func loadEvents(path string) ([]Event, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("replay: read %s: %w", path, err)
+	}
+	var events []Event
+	if err := json.Unmarshal(data, &events); err != nil {
+		return nil, fmt.Errorf("replay: decode %s: %w", path, err)
+	}
+	return events, nil
+}
+
+// replay walks events in timestamp order, rebuilding the graph from the
+// INIT event and checking every MATCHED/MSG transition against the
+// invariants a correct maximal-matching run must satisfy.
+func replay(events []Event) (*Report, error) {
+	report := &Report{}
+
+	// Events are recorded concurrently by every node's goroutine onto one
+	// shared channel, so file order only matches timestamp order by luck;
+	// the cross-node "sent after MATCHED" check depends on seeing events
+	// in true timestamp order.
+	sort.Slice(events, func(i, j int) bool { return events[i].Timestamp < events[j].Timestamp })
+
+	var nodes []int
+	neighbors := make(map[int]map[int]bool)
+	weights := make(map[[2]int]float64)
+	matched := make(map[int]int) // node -> partner
+	done := make(map[int]bool)   // node has been MATCHED
+
+	for _, ev := range events {
+		switch ev.Type {
+		case "INIT":
+			nodes = ev.Nodes
+			for _, n := range nodes {
+				neighbors[n] = make(map[int]bool)
+			}
+			for i, e := range ev.Edges {
+				neighbors[e[0]][e[1]] = true
+				neighbors[e[1]][e[0]] = true
+				if i < len(ev.EdgeWeights) {
+					report.Weighted = true
+					weights[[2]int{e[0], e[1]}] = ev.EdgeWeights[i]
+					weights[[2]int{e[1], e[0]}] = ev.EdgeWeights[i]
+				}
+			}
+
+		case "MATCHED":
+			node, partner := ev.Node, ev.Partner
+
+			if done[node] {
+				report.Violations = append(report.Violations,
+					fmt.Sprintf("node %d reported MATCHED more than once", node))
+			}
+			done[node] = true
+
+			if !neighbors[node][partner] {
+				report.Violations = append(report.Violations,
+					fmt.Sprintf("node %d matched with %d, but they were not neighbors at INIT", node, partner))
+			}
+
+			if existing, ok := matched[partner]; ok && existing != node {
+				report.Violations = append(report.Violations,
+					fmt.Sprintf("node %d matched with %d, but %d was already matched with %d", node, partner, partner, existing))
+			}
+			matched[node] = partner
+			if node < partner {
+				report.MatchingWeight += weights[[2]int{node, partner}]
+			}
+
+		case "MSG_SENT":
+			if ev.Msg == nil {
+				continue
+			}
+			if done[ev.Node] && (ev.Msg.Type == "PROPOSE" || ev.Msg.Type == "ACCEPT") {
+				report.Violations = append(report.Violations,
+					fmt.Sprintf("node %d sent %s after being MATCHED", ev.Node, ev.Msg.Type))
+			}
+		}
+	}
+
+	for node, partner := range matched {
+		if other, ok := matched[partner]; !ok || other != node {
+			report.Violations = append(report.Violations,
+				fmt.Sprintf("matched pair (%d, %d) is not mutual", node, partner))
+		}
+	}
+	report.MatchingSize = len(matched) / 2
+
+	if len(nodes) > 0 && len(nodes) <= bruteForceCutoff {
+		// A weighted maximal matching doesn't aim for maximum cardinality,
+		// so comparing MatchingSize against the brute-force maximum isn't a
+		// meaningful invariant once weights are in play; run only the
+		// search that's actually relevant instead of paying for both.
+		if report.Weighted {
+			report.OptimalWeight = bruteForceMaxWeightMatching(nodes, neighbors, weights)
+		} else {
+			report.BruteForceSize = bruteForceMaxMatching(nodes, neighbors)
+			if report.MatchingSize < report.BruteForceSize {
+				report.Violations = append(report.Violations,
+					fmt.Sprintf("matching size %d is below the brute-force maximum %d", report.MatchingSize, report.BruteForceSize))
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// bruteForceMaxMatching computes the true maximum matching size for small
+// graphs by, at each node in turn, trying both leaving it unmatched and
+// pairing it with every still-available neighbor.
+func bruteForceMaxMatching(nodes []int, neighbors map[int]map[int]bool) int {
+	used := make(map[int]bool)
+	var best int
+
+	var search func(idx, size int)
+	search = func(idx, size int) {
+		if size > best {
+			best = size
+		}
+		if idx >= len(nodes) {
+			return
+		}
+
+		n := nodes[idx]
+		if used[n] {
+			search(idx+1, size)
+			return
+		}
+
+		search(idx+1, size) // leave n unmatched
+
+		for m := range neighbors[n] {
+			if used[m] {
+				continue
+			}
+			used[n], used[m] = true, true
+			search(idx+1, size+1)
+			used[n], used[m] = false, false
+		}
+	}
+	search(0, 0)
+	return best
+}
+
+// bruteForceMaxWeightMatching computes the true maximum-weight matching
+// for small graphs with the same leave-it-or-pair-it search as
+// bruteForceMaxMatching, tracking total edge weight instead of pair count.
+func bruteForceMaxWeightMatching(nodes []int, neighbors map[int]map[int]bool, weights map[[2]int]float64) float64 {
+	used := make(map[int]bool)
+	var best float64
+
+	var search func(idx int, weight float64)
+	search = func(idx int, weight float64) {
+		if weight > best {
+			best = weight
+		}
+		if idx >= len(nodes) {
+			return
+		}
+
+		n := nodes[idx]
+		if used[n] {
+			search(idx+1, weight)
+			return
+		}
+
+		search(idx+1, weight) // leave n unmatched
+
+		for m := range neighbors[n] {
+			if used[m] {
+				continue
+			}
+			used[n], used[m] = true, true
+			search(idx+1, weight+weights[[2]int{n, m}])
+			used[n], used[m] = false, false
+		}
+	}
+	search(0, 0)
+	return best
+}
+
+func main() {
+	path := "simulation_events.json"
+	if len(os.Args) > 1 {
+		path = os.Args[1]
+	}
+
+	events, err := loadEvents(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	report, err := replay(events)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	fmt.Printf("Matching size: %d\n", report.MatchingSize)
+	if report.BruteForceSize > 0 {
+		fmt.Printf("Brute-force maximum: %d\n", report.BruteForceSize)
+	}
+	if report.Weighted {
+		fmt.Printf("Matching weight: %.1f\n", report.MatchingWeight)
+		if report.OptimalWeight > 0 {
+			fmt.Printf("Brute-force optimal weight: %.1f\n", report.OptimalWeight)
+		}
+	}
+	if len(report.Violations) == 0 {
+		fmt.Println("No invariant violations found.")
+		return
+	}
+
+	fmt.Printf("%d invariant violation(s) found:\n", len(report.Violations))
+	for _, v := range report.Violations {
+		fmt.Println("  -", v)
+	}
+	os.Exit(1)
+}
@@ -0,0 +1,170 @@
+package main
+
+import "testing"
+
+func initEvent(nodes []int, edges [][2]int) Event {
+	return Event{Type: "INIT", Nodes: nodes, Edges: edges}
+}
+
+func weightedInitEvent(nodes []int, edges [][2]int, weights []float64) Event {
+	ev := initEvent(nodes, edges)
+	ev.EdgeWeights = weights
+	return ev
+}
+
+func matchedEvent(ts int64, node, partner int) Event {
+	return Event{Timestamp: ts, Type: "MATCHED", Node: node, Partner: partner}
+}
+
+func TestReplayCleanRun(t *testing.T) {
+	events := []Event{
+		initEvent([]int{0, 1, 2, 3}, [][2]int{{0, 1}, {2, 3}}),
+		matchedEvent(1, 0, 1),
+		matchedEvent(2, 1, 0),
+		matchedEvent(3, 2, 3),
+		matchedEvent(4, 3, 2),
+	}
+
+	report, err := replay(events)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if len(report.Violations) != 0 {
+		t.Fatalf("unexpected violations: %v", report.Violations)
+	}
+	if report.MatchingSize != 2 {
+		t.Fatalf("MatchingSize = %d, want 2", report.MatchingSize)
+	}
+	if report.BruteForceSize != 2 {
+		t.Fatalf("BruteForceSize = %d, want 2", report.BruteForceSize)
+	}
+}
+
+func TestReplayDetectsDoubleMatch(t *testing.T) {
+	events := []Event{
+		initEvent([]int{0, 1, 2}, [][2]int{{0, 1}, {1, 2}}),
+		matchedEvent(1, 1, 0), // 1 and 0 match...
+		matchedEvent(2, 0, 1), // ...mutually, no conflict yet
+		matchedEvent(3, 2, 1), // 2 also claims 1, who's already taken by 0
+	}
+
+	report, err := replay(events)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if len(report.Violations) == 0 {
+		t.Fatal("expected a violation for node 1 being claimed by both 0 and 2, got none")
+	}
+	// This one conflict should read as one violation caught in the loop
+	// plus the final mutuality scan's independent confirmation, not an
+	// inflated count from the loop checking the same matched[partner]
+	// entry twice in a row.
+	if len(report.Violations) != 2 {
+		t.Fatalf("got %d violations for a single conflict, want 2: %v", len(report.Violations), report.Violations)
+	}
+	if report.Violations[0] == report.Violations[1] {
+		t.Fatalf("violations are literal duplicates: %v", report.Violations)
+	}
+}
+
+func TestReplayDetectsNonNeighborMatch(t *testing.T) {
+	events := []Event{
+		initEvent([]int{0, 1, 2}, [][2]int{{0, 1}}),
+		matchedEvent(1, 0, 2),
+		matchedEvent(2, 2, 0),
+	}
+
+	report, err := replay(events)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if len(report.Violations) == 0 {
+		t.Fatal("expected a violation for matching non-neighbors, got none")
+	}
+}
+
+func TestReplayDetectsMessageAfterMatched(t *testing.T) {
+	events := []Event{
+		initEvent([]int{0, 1, 2}, [][2]int{{0, 1}, {0, 2}}),
+		matchedEvent(1, 0, 1),
+		matchedEvent(2, 1, 0),
+		{Timestamp: 3, Type: "MSG_SENT", Node: 0, Msg: &Message{Type: "PROPOSE", Sender: 0, Target: 2}},
+	}
+
+	report, err := replay(events)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if len(report.Violations) == 0 {
+		t.Fatal("expected a violation for sending after being matched, got none")
+	}
+}
+
+// TestReplaySortsOutOfFileOrder verifies replay sorts by Timestamp before
+// walking events, not just trusting file order: the MSG_SENT here would
+// read as pre-match (and thus innocent) if replay walked these events in
+// the order listed instead of by timestamp. The match is mutual, so that
+// MSG_SENT is the only possible source of a violation.
+func TestReplaySortsOutOfFileOrder(t *testing.T) {
+	events := []Event{
+		{Timestamp: 3, Type: "MSG_SENT", Node: 0, Msg: &Message{Type: "PROPOSE", Sender: 0, Target: 2}},
+		initEvent([]int{0, 1, 2}, [][2]int{{0, 1}, {0, 2}}),
+		matchedEvent(1, 0, 1),
+		matchedEvent(2, 1, 0),
+	}
+	events[1].Timestamp = 0
+	events[2].Timestamp = 2
+
+	report, err := replay(events)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if len(report.Violations) == 0 {
+		t.Fatal("expected a violation once events are sorted into timestamp order, got none")
+	}
+}
+
+// TestReplayWeighted checks a weighted run reports both the achieved and
+// brute-force optimal matching weight: node 1 has a choice between the
+// heavier edge to 0 (weight 5) and the lighter one to 2 (weight 1), so a
+// run that settles for {1,2} instead of {0,1} falls short of optimal.
+func TestReplayWeighted(t *testing.T) {
+	events := []Event{
+		weightedInitEvent([]int{0, 1, 2}, [][2]int{{0, 1}, {1, 2}}, []float64{5, 1}),
+		matchedEvent(1, 1, 2),
+		matchedEvent(2, 2, 1),
+	}
+
+	report, err := replay(events)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if !report.Weighted {
+		t.Fatal("expected report.Weighted to be true for a log with EdgeWeights")
+	}
+	if report.MatchingWeight != 1 {
+		t.Fatalf("MatchingWeight = %v, want 1", report.MatchingWeight)
+	}
+	if report.OptimalWeight != 5 {
+		t.Fatalf("OptimalWeight = %v, want 5", report.OptimalWeight)
+	}
+	// Settling for the lighter edge isn't an invariant violation (the
+	// algorithm is only a 1/2-approximation), just a below-optimal result.
+	if len(report.Violations) != 0 {
+		t.Fatalf("unexpected violations for a valid if suboptimal matching: %v", report.Violations)
+	}
+}
+
+func TestBruteForceMaxMatching(t *testing.T) {
+	nodes := []int{0, 1, 2, 3}
+	neighbors := map[int]map[int]bool{
+		0: {1: true},
+		1: {0: true, 2: true},
+		2: {1: true, 3: true},
+		3: {2: true},
+	}
+
+	if got := bruteForceMaxMatching(nodes, neighbors); got != 2 {
+		t.Fatalf("bruteForceMaxMatching(path of 4) = %d, want 2", got)
+	}
+}
@@ -0,0 +1,128 @@
+//go:build tcp
+
+// This file is the runnable multi-process counterpart to main.go: instead
+// of simulating every node as a goroutine sharing an in-process channel
+// map, it starts exactly one node as its own OS process talking to its
+// peers over TCPTransport. Run one instance per node, e.g. for a 3-node
+// triangle with edge weights 3 and 5:
+//
+//	go run -tags tcp . -id 0 -addr :9000 -peers 1=localhost:9001,2=localhost:9002 -neighbors 1=3,2=5
+//	go run -tags tcp . -id 1 -addr :9001 -peers 0=localhost:9000,2=localhost:9002 -neighbors 0=3,2=5
+//	go run -tags tcp . -id 2 -addr :9002 -peers 0=localhost:9000,1=localhost:9001 -neighbors 0=5,1=5
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parsePeers parses a -peers flag value like "1=host:port,2=host:port"
+// into a node ID -> address map.
+func parsePeers(s string) (map[int]string, error) {
+	peers := make(map[int]string)
+	for _, part := range strings.Split(s, ",") {
+		if part == "" {
+			continue
+		}
+		idStr, addr, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid -peers entry %q, want id=host:port", part)
+		}
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -peers entry %q: %w", part, err)
+		}
+		peers[id] = addr
+	}
+	return peers, nil
+}
+
+// parseNeighbors parses a -neighbors flag value like "1=3,2=5" into a node
+// ID -> edge weight map.
+func parseNeighbors(s string) (map[int]float64, error) {
+	weights := make(map[int]float64)
+	for _, part := range strings.Split(s, ",") {
+		if part == "" {
+			continue
+		}
+		idStr, wStr, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid -neighbors entry %q, want id=weight", part)
+		}
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -neighbors entry %q: %w", part, err)
+		}
+		w, err := strconv.ParseFloat(wStr, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -neighbors entry %q: %w", part, err)
+		}
+		weights[id] = w
+	}
+	return weights, nil
+}
+
+func main() {
+	id := flag.Int("id", -1, "this node's ID")
+	addr := flag.String("addr", "", "address to listen on, e.g. :9000")
+	peersFlag := flag.String("peers", "", "comma-separated id=host:port list of every other node")
+	neighborsFlag := flag.String("neighbors", "", "comma-separated id=weight list of this node's graph neighbors")
+	out := flag.String("out", "simulation_events.json", "event log output path")
+	flag.Parse()
+
+	if *id < 0 || *addr == "" {
+		fmt.Fprintln(os.Stderr, "usage: go run -tags tcp . -id <id> -addr <host:port> -peers <id=host:port,...> -neighbors <id=weight,...>")
+		os.Exit(2)
+	}
+
+	peers, err := parsePeers(*peersFlag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	neighborWeights, err := parseNeighbors(*neighborsFlag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	transport, err := NewTCPTransport(*id, *addr, peers, neighborWeights)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer transport.Close()
+
+	recorder := NewEventRecorder(*out)
+	// Same headroom as the in-process simulation in main.go.
+	limiter := NewRateLimiter(20.0, 5.0, time.Minute)
+	defer limiter.Close()
+
+	node := InitNode(*id, neighborWeights, transport, limiter, recorder)
+	node.makePairs()
+	recorder.Close()
+
+	checkNeighborAgreement(transport, *id, neighborWeights)
+	fmt.Printf("Node %d finished: pair=%d\n", *id, node.pair)
+}
+
+// checkNeighborAgreement warns about any of this node's own -neighbors
+// entries that it exchanged a bootstrap handshake with and whose own
+// -neighbors flag didn't list this node back, which would mean the two processes were started with
+// mismatched views of the graph.
+func checkNeighborAgreement(transport *TCPTransport, self int, neighborWeights map[int]float64) {
+	for peer := range neighborWeights {
+		announced := transport.PeerNeighbors(peer)
+		if announced == nil {
+			continue // never exchanged a handshake with this peer
+		}
+		if !slices.Contains(announced, self) {
+			fmt.Fprintf(os.Stderr, "warning: node %d's neighbor list %v doesn't include this node (%d); check -neighbors on both ends\n", peer, announced, self)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+)
+
+// Transport abstracts how a Node exchanges Messages with its peers, so the
+// maximal-matching algorithm can run either as goroutines sharing an
+// in-process channel map or as independent OS processes talking over the
+// network.
+type Transport interface {
+	// Send delivers m to the node identified by to.
+	Send(to int, m Message) error
+	// Recv blocks until the next Message addressed to this node arrives.
+	Recv() (Message, error)
+}
+
+// ChannelTransport is the original in-process Transport: every node owns a
+// buffered inbox channel and Send writes directly into the recipient's.
+type ChannelTransport struct {
+	inbox   chan Message
+	network map[int]chan Message
+}
+
+// NewChannelTransport builds a Transport backed by the shared channel map
+// used by the goroutine-based simulation in main().
+func NewChannelTransport(inbox chan Message, network map[int]chan Message) *ChannelTransport {
+	return &ChannelTransport{inbox: inbox, network: network}
+}
+
+func (t *ChannelTransport) Send(to int, m Message) error {
+	select {
+	case t.network[to] <- m:
+		return nil
+	default:
+		// Drop message if the channel is full, as before, to prevent a
+		// slow reader from deadlocking the whole simulation.
+		return fmt.Errorf("transport: channel to node %d is full, message dropped", to)
+	}
+}
+
+func (t *ChannelTransport) Recv() (Message, error) {
+	m, ok := <-t.inbox
+	if !ok {
+		return Message{}, fmt.Errorf("transport: inbox closed")
+	}
+	return m, nil
+}
+
+// bootstrapInfo is exchanged once per connection, in both directions, so
+// each side learns the other's node ID and current neighbor list before any
+// Message traffic starts.
+type bootstrapInfo struct {
+	NodeID    int   `json:"node_id"`
+	Neighbors []int `json:"neighbors,omitempty"`
+}
+
+// TCPTransport lets a Node run as an independent OS process, exchanging
+// Messages with its peers over plain TCP connections instead of a shared
+// in-process map. Peer addresses are supplied up front; a short bootstrap
+// handshake on each connection exchanges node IDs and neighbor lists.
+//
+// Each ordered pair of nodes uses one connection per direction: outConns
+// holds the sockets this node dialed itself, used only for writing via
+// Send; every socket this node accepted is read by its own handleConn
+// goroutine and never touches outConns. Keeping the two separate is what
+// lets a node both send to and receive from the same peer — sharing one
+// map let whichever side dialed last silently steal the other direction's
+// socket out from under its reader.
+type TCPTransport struct {
+	self          int
+	selfNeighbors []int // this node's neighbor IDs, sent with every handshake
+	ln            net.Listener
+	inbox         chan Message
+	peers         map[int]string // node ID -> "host:port"
+
+	mu            sync.Mutex
+	outConns      map[int]net.Conn
+	peerNeighbors map[int][]int // node ID -> neighbor list it announced
+}
+
+// NewTCPTransport starts listening on addr for node self. peers maps every
+// neighbor's node ID to the "host:port" it listens on; connections to them
+// are opened lazily on first Send. neighborWeights is this node's current
+// set of graph neighbors, announced to every peer during the bootstrap
+// handshake so each side learns the other's neighbor list, not just its ID.
+func NewTCPTransport(self int, addr string, peers map[int]string, neighborWeights map[int]float64) (*TCPTransport, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("transport: listen on %s: %w", addr, err)
+	}
+
+	selfNeighbors := make([]int, 0, len(neighborWeights))
+	for id := range neighborWeights {
+		selfNeighbors = append(selfNeighbors, id)
+	}
+	sort.Ints(selfNeighbors)
+
+	t := &TCPTransport{
+		self:          self,
+		selfNeighbors: selfNeighbors,
+		ln:            ln,
+		inbox:         make(chan Message, 1000),
+		peers:         peers,
+		outConns:      make(map[int]net.Conn),
+		peerNeighbors: make(map[int][]int),
+	}
+	go t.acceptLoop()
+	return t, nil
+}
+
+// PeerNeighbors returns the neighbor list node id announced during its
+// bootstrap handshake, or nil if no handshake with that peer has completed
+// yet (e.g. it has neither dialed nor been dialed by this node).
+func (t *TCPTransport) PeerNeighbors(id int) []int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.peerNeighbors[id]
+}
+
+func (t *TCPTransport) acceptLoop() {
+	for {
+		conn, err := t.ln.Accept()
+		if err != nil {
+			return
+		}
+		go t.handleConn(conn)
+	}
+}
+
+// handleConn reads the bootstrap handshake off a freshly accepted
+// connection, replies with this node's own handshake info, then forwards
+// every Message the peer sends onto this node's inbox until the connection
+// closes. This connection is purely for reading: it is never stored
+// alongside the sockets dial opens for writing, so a node that both sends
+// to and receives from the same peer never fights itself over which
+// socket a Send should use.
+func (t *TCPTransport) handleConn(conn net.Conn) {
+	dec := json.NewDecoder(conn)
+
+	var info bootstrapInfo
+	if err := dec.Decode(&info); err != nil {
+		conn.Close()
+		return
+	}
+
+	reply := bootstrapInfo{NodeID: t.self, Neighbors: t.selfNeighbors}
+	if err := json.NewEncoder(conn).Encode(reply); err != nil {
+		conn.Close()
+		return
+	}
+
+	t.mu.Lock()
+	t.peerNeighbors[info.NodeID] = info.Neighbors
+	t.mu.Unlock()
+
+	for {
+		var m Message
+		if err := dec.Decode(&m); err != nil {
+			return
+		}
+		t.inbox <- m
+	}
+}
+
+// dial lazily opens and bootstraps the outbound connection to node `to`,
+// reusing one that already exists. The returned connection is used only
+// for writing: `to`'s replies arrive over the connection it dials back to
+// this node, read by this node's own handleConn.
+func (t *TCPTransport) dial(to int) (net.Conn, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if conn, ok := t.outConns[to]; ok {
+		return conn, nil
+	}
+
+	addr, ok := t.peers[to]
+	if !ok {
+		return nil, fmt.Errorf("transport: no address known for node %d", to)
+	}
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("transport: dial node %d at %s: %w", to, addr, err)
+	}
+	if err := json.NewEncoder(conn).Encode(bootstrapInfo{NodeID: t.self, Neighbors: t.selfNeighbors}); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("transport: bootstrap handshake with node %d: %w", to, err)
+	}
+
+	var reply bootstrapInfo
+	if err := json.NewDecoder(conn).Decode(&reply); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("transport: bootstrap reply from node %d: %w", to, err)
+	}
+	t.peerNeighbors[to] = reply.Neighbors
+
+	t.outConns[to] = conn
+	return conn, nil
+}
+
+func (t *TCPTransport) Send(to int, m Message) error {
+	conn, err := t.dial(to)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(conn).Encode(m); err != nil {
+		return fmt.Errorf("transport: send to node %d: %w", to, err)
+	}
+	return nil
+}
+
+func (t *TCPTransport) Recv() (Message, error) {
+	m, ok := <-t.inbox
+	if !ok {
+		return Message{}, fmt.Errorf("transport: inbox closed")
+	}
+	return m, nil
+}
+
+// Close stops accepting new connections and closes every connection this
+// node dialed; connections accepted from peers are not closed.
+func (t *TCPTransport) Close() error {
+	t.ln.Close()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, conn := range t.outConns {
+		conn.Close()
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+// Package mutex provides Peterson's filter algorithm generalized to an
+// arbitrary number of competing threads.
+package mutex
+
+import (
+	"runtime"
+	"sync/atomic"
+)
+
+// Filter is a mutual-exclusion lock for n threads, identified by the
+// integer IDs 0..n-1, implementing Peterson's filter algorithm:
+// each thread climbs n-1 levels, at each one yielding to any other thread
+// still present at that level if it is the most recent victim.
+type Filter struct {
+	n      int
+	level  []atomic.Int32
+	victim []atomic.Int32
+}
+
+// NewFilter returns a Filter lock usable by n distinct thread IDs.
+func NewFilter(n int) *Filter {
+	return &Filter{
+		n:      n,
+		level:  make([]atomic.Int32, n),
+		victim: make([]atomic.Int32, n),
+	}
+}
+
+// Lock blocks until thread id may safely enter the critical section.
+func (f *Filter) Lock(id int) {
+	for l := 1; l < f.n; l++ {
+		f.level[id].Store(int32(l))
+		f.victim[l].Store(int32(id))
+
+		for f.existsAtLeast(id, l) && f.victim[l].Load() == int32(id) {
+			runtime.Gosched()
+		}
+	}
+}
+
+// Unlock releases the critical section held by thread id.
+func (f *Filter) Unlock(id int) {
+	f.level[id].Store(0)
+}
+
+// existsAtLeast reports whether some thread other than id is at level l
+// or higher.
+func (f *Filter) existsAtLeast(id, l int) bool {
+	for k := 0; k < f.n; k++ {
+		if k != id && f.level[k].Load() >= int32(l) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package mutex
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+const filterTestIters = 2000
+
+// TestFilterMatchesMutexBaseline stress-tests Filter against a plain
+// sync.Mutex across 2..16 competing goroutines: both must count the exact
+// same number of increments if Filter is providing real mutual exclusion.
+func TestFilterMatchesMutexBaseline(t *testing.T) {
+	for n := 2; n <= 16; n++ {
+		n := n
+		t.Run(fmt.Sprintf("n=%d", n), func(t *testing.T) {
+			want := countWithMutex(n)
+			got := countWithFilter(n)
+			if got != want {
+				t.Fatalf("Filter(%d): got %d increments, want %d", n, got, want)
+			}
+		})
+	}
+}
+
+func countWithFilter(n int) int {
+	f := NewFilter(n)
+	counter := 0
+
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for id := 0; id < n; id++ {
+		go func(id int) {
+			defer wg.Done()
+			for i := 0; i < filterTestIters; i++ {
+				f.Lock(id)
+				counter++
+				f.Unlock(id)
+			}
+		}(id)
+	}
+	wg.Wait()
+	return counter
+}
+
+func countWithMutex(n int) int {
+	var mu sync.Mutex
+	counter := 0
+
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for id := 0; id < n; id++ {
+		go func() {
+			defer wg.Done()
+			for i := 0; i < filterTestIters; i++ {
+				mu.Lock()
+				counter++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return counter
+}